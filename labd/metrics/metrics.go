@@ -0,0 +1,171 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics holds the daemon's Prometheus registry and the
+// instrumentation used across labd's routers.
+package metrics
+
+import (
+	"github.com/Netflix/p2plab/metadata"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is the daemon's Prometheus registry along with the collectors
+// shared across routers. It is swappable so tests can register a scratch
+// registry instead of the process-wide default.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	BenchmarksTotal   *prometheus.CounterVec
+	BenchmarksRunning prometheus.Gauge
+	PlanDuration      prometheus.Histogram
+	SeedDuration      prometheus.Histogram
+	TaskDuration      *prometheus.HistogramVec
+}
+
+// New creates a Metrics with its own registry and registers all collectors
+// against it.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		BenchmarksTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "p2plab",
+			Name:      "benchmarks_total",
+			Help:      "Total number of benchmarks that have reached a terminal status.",
+		}, []string{"status"}),
+		BenchmarksRunning: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "p2plab",
+			Name:      "benchmarks_in_flight",
+			Help:      "Number of benchmarks currently executing.",
+		}),
+		PlanDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "p2plab",
+			Name:      "scenario_plan_duration_seconds",
+			Help:      "Time spent building a scenario plan.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		SeedDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "p2plab",
+			Name:      "scenario_seed_duration_seconds",
+			Help:      "Time spent planning a scenario's seed tasks.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		TaskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "p2plab",
+			Name:      "task_duration_seconds",
+			Help:      "Per-task execution latency during a benchmark run.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+	}
+
+	m.Registry.MustRegister(
+		m.BenchmarksTotal,
+		m.BenchmarksRunning,
+		m.PlanDuration,
+		m.SeedDuration,
+		m.TaskDuration,
+	)
+
+	return m
+}
+
+// Run accumulates one benchmark's task-duration observations in their own
+// local registry, separate from the daemon-wide collectors above. Gathering
+// the shared registry and subtracting a baseline breaks down the moment two
+// benchmarks overlap, since both would observe into the same TaskDuration
+// collector; a Run's local histogram only ever sees that one benchmark's
+// tasks.
+type Run struct {
+	metrics  *Metrics
+	registry *prometheus.Registry
+	task     *prometheus.HistogramVec
+}
+
+// NewRun starts tracking a new benchmark run.
+func (m *Metrics) NewRun() *Run {
+	task := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "p2plab",
+		Name:      "task_duration_seconds",
+		Help:      "Per-task execution latency during a benchmark run.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(task)
+
+	return &Run{metrics: m, registry: registry, task: task}
+}
+
+// ObserveTaskDuration records a task's latency both in this run's private
+// histogram and in the daemon-wide TaskDuration collector, so /metrics still
+// reports cluster-wide latency alongside the per-report snapshot.
+func (r *Run) ObserveTaskDuration(taskType string, seconds float64) {
+	r.metrics.TaskDuration.WithLabelValues(taskType).Observe(seconds)
+	r.task.WithLabelValues(taskType).Observe(seconds)
+}
+
+// Snapshot gathers this run's private registry into a metadata.MetricsSnapshot
+// for persistence. Unlike reading the daemon-wide registry, it can't be
+// polluted by another benchmark running concurrently, and it keeps each
+// histogram's bucket boundaries and sum so a report's latency distribution
+// can be reconstructed later.
+func (r *Run) Snapshot() (metadata.MetricsSnapshot, error) {
+	families, err := r.registry.Gather()
+	if err != nil {
+		return metadata.MetricsSnapshot{}, err
+	}
+
+	return snapshotFamilies(families), nil
+}
+
+func snapshotFamilies(families []*dto.MetricFamily) metadata.MetricsSnapshot {
+	snapshot := metadata.MetricsSnapshot{
+		Counters:   make(map[string]float64),
+		Gauges:     make(map[string]float64),
+		Histograms: make(map[string]metadata.HistogramSnapshot),
+	}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			name := sampleName(family.GetName(), metric)
+			switch {
+			case metric.Counter != nil:
+				snapshot.Counters[name] = metric.Counter.GetValue()
+			case metric.Gauge != nil:
+				snapshot.Gauges[name] = metric.Gauge.GetValue()
+			case metric.Histogram != nil:
+				buckets := make(map[float64]uint64, len(metric.Histogram.GetBucket()))
+				for _, b := range metric.Histogram.GetBucket() {
+					buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				}
+				snapshot.Histograms[name] = metadata.HistogramSnapshot{
+					SampleCount: metric.Histogram.GetSampleCount(),
+					SampleSum:   metric.Histogram.GetSampleSum(),
+					Buckets:     buckets,
+				}
+			}
+		}
+	}
+
+	return snapshot
+}
+
+func sampleName(name string, metric *dto.Metric) string {
+	for _, label := range metric.GetLabel() {
+		name += "_" + label.GetValue()
+	}
+	return name
+}
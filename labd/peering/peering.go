@@ -0,0 +1,132 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package peering fans out node-list and task-execution RPCs to remote
+// labd instances that have been established as peers, so a scenario can
+// draw nodes from more than one cluster.
+package peering
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Netflix/p2plab/daemon"
+	"github.com/Netflix/p2plab/metadata"
+	"github.com/Netflix/p2plab/pkg/httputil"
+	"github.com/pkg/errors"
+)
+
+// LabelPrefix namespaces the labels a remote peer's nodes are tagged with,
+// e.g. "peer:us-west" for a peer named "us-west".
+const LabelPrefix = "peer:"
+
+// NewToken mints a random bearer token used to authenticate a peer's RPCs
+// to this cluster.
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate peer token")
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// FetchNodes retrieves the current node set of a peered cluster by calling
+// its daemon's authenticated peer node-list route, RequireAuth-guarded and
+// scoped to the cluster the peering's token was minted for.
+func FetchNodes(ctx context.Context, client *httputil.Client, p metadata.Peering) ([]metadata.Node, error) {
+	req, err := client.NewRequest(ctx, http.MethodGet, p.Endpoint, "/peers/nodes/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+
+	var nodes []metadata.Node
+	err = client.Do(req, &nodes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list nodes from peer %q", p.Name)
+	}
+
+	for i := range nodes {
+		nodes[i].Labels = append(nodes[i].Labels, LabelPrefix+p.Name)
+	}
+
+	return nodes, nil
+}
+
+// Authenticate validates the Bearer token on an incoming request against the
+// peerings this cluster knows about (both tokens it has issued and peers it
+// has established), returning the matching Peering. Routes that hand out
+// peer-only data, such as peerrouter's /peers/nodes/json, must call this (or
+// wrap themselves in RequireAuth) before treating the caller as an
+// authorized peer.
+func Authenticate(ctx context.Context, db metadata.DB, r *http.Request) (metadata.Peering, error) {
+	const prefix = "Bearer "
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return metadata.Peering{}, errors.New("missing bearer token")
+	}
+	token := strings.TrimPrefix(auth, prefix)
+	if token == "" {
+		return metadata.Peering{}, errors.New("missing bearer token")
+	}
+
+	peerings, err := db.ListPeerings(ctx)
+	if err != nil {
+		return metadata.Peering{}, err
+	}
+
+	for _, p := range peerings {
+		if p.Token != "" && p.Token == token {
+			return p, nil
+		}
+	}
+
+	return metadata.Peering{}, errors.New("invalid peer token")
+}
+
+// RequireAuth wraps a route handler so it only runs once the request
+// presents a valid Bearer token for one of this cluster's peerings.
+// Unauthenticated or unrecognized callers get a 401 instead of reaching the
+// wrapped handler.
+func RequireAuth(db metadata.DB, next daemon.HandlerFunc) daemon.HandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		p, err := Authenticate(ctx, db, r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		}
+
+		return next(withPeering(ctx, p), w, r, vars)
+	}
+}
+
+type peeringContextKey struct{}
+
+// withPeering attaches the authenticated Peering to ctx so a wrapped handler
+// can identify which peer is calling it.
+func withPeering(ctx context.Context, p metadata.Peering) context.Context {
+	return context.WithValue(ctx, peeringContextKey{}, p)
+}
+
+// FromContext returns the Peering that RequireAuth authenticated the current
+// request as, if any.
+func FromContext(ctx context.Context) (metadata.Peering, bool) {
+	p, ok := ctx.Value(peeringContextKey{}).(metadata.Peering)
+	return p, ok
+}
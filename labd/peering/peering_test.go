@@ -0,0 +1,92 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peering
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Netflix/p2plab/metadata"
+)
+
+// fakeDB implements metadata.DB by embedding the (nil) interface and
+// overriding only the methods Authenticate needs; calling anything else
+// panics on the nil embedded interface, which is fine since these tests
+// never exercise it.
+type fakeDB struct {
+	metadata.DB
+	peerings []metadata.Peering
+}
+
+func (f *fakeDB) ListPeerings(ctx context.Context) ([]metadata.Peering, error) {
+	return f.peerings, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	db := &fakeDB{peerings: []metadata.Peering{
+		{Name: "us-west", Token: "good-token", ClusterID: "cluster-a"},
+	}}
+
+	r := httptest.NewRequest(http.MethodGet, "/peers/nodes/json", nil)
+	r.Header.Set("Authorization", "Bearer good-token")
+
+	p, err := Authenticate(context.Background(), db, r)
+	if err != nil {
+		t.Fatalf("Authenticate returned error for valid token: %v", err)
+	}
+	if p.Name != "us-west" || p.ClusterID != "cluster-a" {
+		t.Fatalf("Authenticate returned wrong peering: %+v", p)
+	}
+}
+
+func TestAuthenticateRejectsBadToken(t *testing.T) {
+	db := &fakeDB{peerings: []metadata.Peering{
+		{Name: "us-west", Token: "good-token"},
+	}}
+
+	r := httptest.NewRequest(http.MethodGet, "/peers/nodes/json", nil)
+	r.Header.Set("Authorization", "Bearer wrong-token")
+
+	if _, err := Authenticate(context.Background(), db, r); err == nil {
+		t.Fatal("Authenticate did not reject an invalid token")
+	}
+}
+
+func TestAuthenticateRejectsMissingHeader(t *testing.T) {
+	db := &fakeDB{peerings: []metadata.Peering{
+		{Name: "us-west", Token: "good-token"},
+	}}
+
+	r := httptest.NewRequest(http.MethodGet, "/peers/nodes/json", nil)
+
+	if _, err := Authenticate(context.Background(), db, r); err == nil {
+		t.Fatal("Authenticate did not reject a request with no Authorization header")
+	}
+}
+
+func TestAuthenticateRejectsUnissuedToken(t *testing.T) {
+	db := &fakeDB{peerings: []metadata.Peering{
+		{Name: "us-west", Token: ""},
+	}}
+
+	r := httptest.NewRequest(http.MethodGet, "/peers/nodes/json", nil)
+	r.Header.Set("Authorization", "Bearer ")
+
+	if _, err := Authenticate(context.Background(), db, r); err == nil {
+		t.Fatal("Authenticate did not reject an empty bearer token against an unestablished peering")
+	}
+}
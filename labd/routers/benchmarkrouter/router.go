@@ -21,11 +21,15 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Netflix/p2plab"
 	"github.com/Netflix/p2plab/daemon"
+	"github.com/Netflix/p2plab/errdefs"
 	"github.com/Netflix/p2plab/labd/controlapi"
+	"github.com/Netflix/p2plab/labd/metrics"
+	"github.com/Netflix/p2plab/labd/peering"
 	"github.com/Netflix/p2plab/metadata"
 	"github.com/Netflix/p2plab/nodes"
 	"github.com/Netflix/p2plab/peer"
@@ -48,10 +52,22 @@ type router struct {
 	ts      *transformers.Transformers
 	seeder  *peer.Peer
 	builder p2plab.Builder
+	metrics *metrics.Metrics
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
 }
 
-func New(db metadata.DB, client *httputil.Client, ts *transformers.Transformers, seeder *peer.Peer, builder p2plab.Builder) daemon.Router {
-	return &router{db, client, ts, seeder, builder}
+func New(db metadata.DB, client *httputil.Client, ts *transformers.Transformers, seeder *peer.Peer, builder p2plab.Builder, m *metrics.Metrics) daemon.Router {
+	return &router{
+		db:      db,
+		client:  client,
+		ts:      ts,
+		seeder:  seeder,
+		builder: builder,
+		metrics: m,
+		cancels: make(map[string]context.CancelFunc),
+	}
 }
 
 func (s *router) Routes() []daemon.Route {
@@ -62,13 +78,37 @@ func (s *router) Routes() []daemon.Route {
 		daemon.NewGetRoute("/benchmarks/{id}/report/json", s.getBenchmarkReportById),
 		// POST
 		daemon.NewPostRoute("/benchmarks/create", s.postBenchmarksCreate),
+		daemon.NewPostRoute("/benchmarks/{id}/resume", s.postBenchmarksResume),
 		// PUT
 		daemon.NewPutRoute("/benchmarks/label", s.putBenchmarksLabel),
 		// DELETE
 		daemon.NewDeleteRoute("/benchmarks/delete", s.deleteBenchmarks),
+		daemon.NewDeleteRoute("/benchmarks/{id}/cancel", s.deleteBenchmarksCancel),
 	}
 }
 
+// setCancel installs cancel as the cancel func for bid. Following the same
+// pattern as gonet's deadlineTimer, an entry already registered for bid is
+// cancelled and replaced atomically under the lock, so a benchmark retried
+// under a reused id can never leak its predecessor's timer.
+func (s *router) setCancel(bid string, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.cancels[bid]; ok {
+		prev()
+	}
+	s.cancels[bid] = cancel
+}
+
+// dropCancel removes bid's cancel func once the benchmark has reached a
+// terminal state, without invoking it.
+func (s *router) dropCancel(bid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, bid)
+}
+
 func (s *router) getBenchmarks(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	benchmarks, err := s.db.ListBenchmarks(ctx)
 	if err != nil {
@@ -98,7 +138,22 @@ func (s *router) getBenchmarkReportById(ctx context.Context, w http.ResponseWrit
 	return daemon.WriteJSON(w, &report)
 }
 
-func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) (err error) {
+	var cancelled bool
+	run := s.metrics.NewRun()
+	s.metrics.BenchmarksRunning.Inc()
+	defer s.metrics.BenchmarksRunning.Dec()
+	defer func() {
+		status := "done"
+		switch {
+		case err != nil:
+			status = "error"
+		case cancelled:
+			status = "cancelled"
+		}
+		s.metrics.BenchmarksTotal.WithLabelValues(status).Inc()
+	}()
+
 	noReset := false
 	if r.FormValue("no-reset") != "" {
 		var err error
@@ -123,39 +178,47 @@ func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter
 	bid := fmt.Sprintf("%s-%s-%d", cid, sid, time.Now().UnixNano())
 	w.Header().Add(controlapi.ResourceID, bid)
 
+	var cancel context.CancelFunc
+	if deadline := r.FormValue("deadline"); deadline != "" {
+		d, err := time.ParseDuration(deadline)
+		if err != nil {
+			return errors.Wrap(err, "invalid deadline")
+		}
+		ctx, cancel = context.WithDeadline(ctx, time.Now().Add(d))
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	s.setCancel(bid, cancel)
+	defer func() {
+		s.dropCancel(bid)
+		cancel()
+	}()
+
 	ctx, logger := logutil.WithResponseLogger(ctx, w)
 	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
 		return c.Str("bid", bid)
 	})
 
-	zerolog.Ctx(ctx).Info().Msg("Retrieving nodes in cluster")
-	mns, err := s.db.ListNodes(ctx, cid)
+	lset, ns, peerSeederAddrs, err := s.collectNodes(ctx, cid)
 	if err != nil {
 		return err
 	}
 
-	var ns []p2plab.Node
-	lset := query.NewLabeledSet()
-	for _, n := range mns {
-		node := controlapi.NewNode(s.client, n)
-		lset.Add(node)
-		ns = append(ns, node)
-	}
-
+	var adjacency metadata.Adjacency
 	if !noReset {
 		err = nodes.Update(ctx, s.builder, ns)
 		if err != nil {
 			return errors.Wrap(err, "failed to update cluster")
 		}
 
-		err = nodes.Connect(ctx, ns)
+		adjacency, err = nodes.Connect(ctx, ns, scenario.Definition.Topology)
 		if err != nil {
 			return errors.Wrap(err, "failed to connect cluster")
 		}
 	}
 
 	zerolog.Ctx(ctx).Info().Msg("Creating scenario plan")
-	plan, queries, err := scenarios.Plan(ctx, scenario.Definition, s.ts, s.seeder, lset)
+	plan, queries, err := scenarios.Plan(ctx, scenario.Definition, s.ts, s.seeder, lset, s.metrics, s.db)
 	if err != nil {
 		return errors.Wrap(err, "failed to create scenario plan")
 	}
@@ -183,22 +246,34 @@ func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter
 	for _, addr := range s.seeder.Host().Addrs() {
 		seederAddrs = append(seederAddrs, fmt.Sprintf("%s/p2p/%s", addr, s.seeder.Host().ID()))
 	}
+	seederAddrs = append(seederAddrs, peerSeederAddrs...)
 
 	zerolog.Ctx(ctx).Info().Msg("Executing scenario plan")
-	execution, err := scenarios.Run(ctx, lset, plan, seederAddrs)
-	if err != nil {
-		return errors.Wrap(err, "failed to run scenario plan")
+	execution, runErr := scenarios.Run(ctx, lset, plan, seederAddrs)
+	cancelled = ctx.Err() != nil
+
+	for _, node := range execution.Report {
+		for _, task := range node.Tasks {
+			run.ObserveTaskDuration(string(task.Type), task.End.Sub(task.Start).Seconds())
+		}
 	}
 
 	report := metadata.Report{
 		Summary: metadata.ReportSummary{
 			TotalTime: execution.End.Sub(execution.Start),
+			Adjacency: adjacency,
 		},
 		Nodes:   execution.Report,
 		Queries: queries,
 	}
 	report.Aggregates = reports.ComputeAggregates(report.Nodes)
 
+	metricsSnapshot, err := run.Snapshot()
+	if err != nil {
+		return errors.Wrap(err, "failed to snapshot metrics")
+	}
+	report.Summary.Metrics = metricsSnapshot
+
 	jaegerUI := os.Getenv("JAEGER_UI")
 	if jaegerUI != "" {
 		sc, ok := execution.Span.Context().(jaeger.SpanContext)
@@ -207,16 +282,34 @@ func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter
 		}
 	}
 
+	// Persist on a context stripped of ctx's cancellation/deadline: ctx is
+	// exactly what just fired (cancelled above or deadline-expired), and if
+	// s.db.Update ever starts honoring context cancellation, persisting the
+	// partial report and final benchmark status must not be skipped because
+	// the same ctx that ended the run is still considered "done".
+	pctx := context.WithoutCancel(ctx)
+
+	// A genuine (non-cancellation) runErr still leaves benchmark.Plan and
+	// whatever nodes did finish worth keeping: persist them as BenchmarkError
+	// so postBenchmarksResume has a report row to resume from, same as it
+	// does for the cancelled case.
+	benchmark.Status = metadata.BenchmarkDone
+	switch {
+	case cancelled:
+		benchmark.Status = metadata.BenchmarkCancelled
+	case runErr != nil:
+		benchmark.Status = metadata.BenchmarkError
+	}
+
 	zerolog.Ctx(ctx).Info().Msg("Updating benchmark metadata")
-	err = s.db.Update(ctx, func(tx *bolt.Tx) error {
-		tctx := metadata.WithTransactionContext(ctx, tx)
+	err = s.db.Update(pctx, func(tx *bolt.Tx) error {
+		tctx := metadata.WithTransactionContext(pctx, tx)
 
 		err := s.db.CreateReport(tctx, benchmark.ID, report)
 		if err != nil {
 			return errors.Wrap(err, "failed to create report")
 		}
 
-		benchmark.Status = metadata.BenchmarkDone
 		_, err = s.db.UpdateBenchmark(tctx, benchmark)
 		if err != nil {
 			return errors.Wrap(err, "failed to update benchmark")
@@ -228,9 +321,162 @@ func (s *router) postBenchmarksCreate(ctx context.Context, w http.ResponseWriter
 		return err
 	}
 
+	if runErr != nil && !cancelled {
+		return errors.Wrap(runErr, "failed to run scenario plan")
+	}
+
 	return nil
 }
 
+// collectNodes gathers every node a benchmark against cid can draw from:
+// nodes in the local cluster plus, for each established peering, nodes
+// fetched from that peer's cluster. It returns the combined set alongside
+// the peers' advertised seeder addresses.
+func (s *router) collectNodes(ctx context.Context, cid string) (p2plab.LabeledSet, []p2plab.Node, []string, error) {
+	zerolog.Ctx(ctx).Info().Msg("Retrieving nodes in cluster")
+	mns, err := s.db.ListNodes(ctx, cid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var ns []p2plab.Node
+	lset := query.NewLabeledSet()
+	for _, n := range mns {
+		node := controlapi.NewNode(s.client, n)
+		lset.Add(node)
+		ns = append(ns, node)
+	}
+
+	zerolog.Ctx(ctx).Info().Msg("Retrieving nodes from peered clusters")
+	peerings, err := s.db.ListPeerings(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var peerSeederAddrs []string
+	for _, p := range peerings {
+		if !p.Established {
+			// A token we've issued to someone else, not a peer we've
+			// established ourselves; it has no Endpoint to dial.
+			continue
+		}
+
+		peerNodes, err := peering.FetchNodes(ctx, s.client, p)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "failed to fetch nodes from peer %q", p.Name)
+		}
+
+		for _, n := range peerNodes {
+			node := controlapi.NewNode(s.client, n)
+			lset.Add(node)
+			ns = append(ns, node)
+		}
+
+		peerSeederAddrs = append(peerSeederAddrs, p.SeederAddrs...)
+	}
+
+	return lset, ns, peerSeederAddrs, nil
+}
+
+// postBenchmarksResume loads a previously stored plan and re-issues only
+// the benchmark tasks that never reached metadata.TaskDone, stitching their
+// results into the existing report rather than starting over.
+func (s *router) postBenchmarksResume(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	id := vars["id"]
+
+	benchmark, err := s.db.GetBenchmark(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.db.GetReport(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(map[string]metadata.Task)
+	for nodeID, task := range benchmark.Plan.Benchmark {
+		if !nodeReportDone(report.Nodes, nodeID) {
+			remaining[nodeID] = task
+		}
+	}
+
+	if len(remaining) == 0 {
+		return daemon.WriteJSON(w, &report)
+	}
+
+	zerolog.Ctx(ctx).Info().Str("bid", id).Int("remaining", len(remaining)).Msg("Resuming scenario plan")
+
+	lset, _, peerSeederAddrs, err := s.collectNodes(ctx, benchmark.Cluster.ID)
+	if err != nil {
+		return err
+	}
+
+	var seederAddrs []string
+	for _, addr := range s.seeder.Host().Addrs() {
+		seederAddrs = append(seederAddrs, fmt.Sprintf("%s/p2p/%s", addr, s.seeder.Host().ID()))
+	}
+	seederAddrs = append(seederAddrs, peerSeederAddrs...)
+
+	resumePlan := benchmark.Plan
+	resumePlan.Benchmark = remaining
+
+	execution, err := scenarios.Run(ctx, lset, resumePlan, seederAddrs)
+	if err != nil {
+		return errors.Wrap(err, "failed to resume scenario plan")
+	}
+
+	report.Nodes = mergeNodeReports(report.Nodes, execution.Report)
+	report.Aggregates = reports.ComputeAggregates(report.Nodes)
+
+	err = s.db.Update(ctx, func(tx *bolt.Tx) error {
+		tctx := metadata.WithTransactionContext(ctx, tx)
+
+		err := s.db.CreateReport(tctx, id, report)
+		if err != nil {
+			return errors.Wrap(err, "failed to update report")
+		}
+
+		benchmark.Status = metadata.BenchmarkDone
+		_, err = s.db.UpdateBenchmark(tctx, benchmark)
+		if err != nil {
+			return errors.Wrap(err, "failed to update benchmark")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &report)
+}
+
+func nodeReportDone(nodes []metadata.NodeReport, nodeID string) bool {
+	for _, node := range nodes {
+		if node.ID == nodeID {
+			return node.Status == metadata.TaskDone
+		}
+	}
+	return false
+}
+
+func mergeNodeReports(existing, resumed []metadata.NodeReport) []metadata.NodeReport {
+	byID := make(map[string]metadata.NodeReport, len(existing))
+	for _, node := range existing {
+		byID[node.ID] = node
+	}
+	for _, node := range resumed {
+		byID[node.ID] = node
+	}
+
+	merged := make([]metadata.NodeReport, 0, len(byID))
+	for _, node := range byID {
+		merged = append(merged, node)
+	}
+	return merged
+}
+
 func (s *router) putBenchmarksLabel(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	ids := strings.Split(r.FormValue("ids"), ",")
 	addLabels := stringutil.Coalesce(strings.Split(r.FormValue("adds"), ","))
@@ -248,6 +494,20 @@ func (s *router) putBenchmarksLabel(ctx context.Context, w http.ResponseWriter,
 	return daemon.WriteJSON(w, &benchmarks)
 }
 
+func (s *router) deleteBenchmarksCancel(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	id := vars["id"]
+
+	s.mu.Lock()
+	cancel, ok := s.cancels[id]
+	s.mu.Unlock()
+	if !ok {
+		return errors.Wrapf(errdefs.ErrNotFound, "no running benchmark %q", id)
+	}
+
+	cancel()
+	return nil
+}
+
 func (s *router) deleteBenchmarks(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	ids := strings.Split(r.FormValue("ids"), ",")
 
@@ -0,0 +1,64 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarkrouter
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestRouter() *router {
+	return &router{cancels: make(map[string]context.CancelFunc)}
+}
+
+func TestSetCancelDropCancel(t *testing.T) {
+	s := newTestRouter()
+
+	_, cancel := context.WithCancel(context.Background())
+	s.setCancel("bid-1", cancel)
+	if _, ok := s.cancels["bid-1"]; !ok {
+		t.Fatal("setCancel did not register the cancel func")
+	}
+
+	s.dropCancel("bid-1")
+	if _, ok := s.cancels["bid-1"]; ok {
+		t.Fatal("dropCancel did not remove the cancel func")
+	}
+}
+
+func TestSetCancelReplacesAndCancelsPrevious(t *testing.T) {
+	s := newTestRouter()
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	s.setCancel("bid-1", cancel1)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	s.setCancel("bid-1", cancel2)
+
+	if ctx1.Err() == nil {
+		t.Fatal("setCancel did not cancel the previous registration for a reused bid")
+	}
+	if ctx2.Err() != nil {
+		t.Fatal("setCancel unexpectedly cancelled the new registration")
+	}
+	if got := s.cancels["bid-1"]; got == nil {
+		t.Fatal("setCancel did not keep the new cancel func registered")
+	}
+}
+
+func TestDropCancelMissingIsNoop(t *testing.T) {
+	s := newTestRouter()
+	s.dropCancel("never-registered")
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metricsrouter exposes the daemon's Prometheus registry over HTTP.
+package metricsrouter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Netflix/p2plab/daemon"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type router struct {
+	registry *prometheus.Registry
+}
+
+func New(registry *prometheus.Registry) daemon.Router {
+	return &router{registry}
+}
+
+func (s *router) Routes() []daemon.Route {
+	return []daemon.Route{
+		// GET
+		daemon.NewGetRoute("/metrics", s.getMetrics),
+	}
+}
+
+func (s *router) getMetrics(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package peerrouter lets a labd instance mint and accept peering tokens so
+// a scenario can span nodes managed by other labd instances.
+package peerrouter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Netflix/p2plab/daemon"
+	"github.com/Netflix/p2plab/labd/peering"
+	"github.com/Netflix/p2plab/metadata"
+	"github.com/Netflix/p2plab/peer"
+	"github.com/pkg/errors"
+)
+
+type router struct {
+	db     metadata.DB
+	seeder *peer.Peer
+}
+
+func New(db metadata.DB, seeder *peer.Peer) daemon.Router {
+	return &router{db, seeder}
+}
+
+func (s *router) Routes() []daemon.Route {
+	return []daemon.Route{
+		// GET
+		daemon.NewGetRoute("/peers/json", s.getPeers),
+		daemon.NewGetRoute("/peers/{name}/json", s.getPeerByName),
+		daemon.NewGetRoute("/peers/nodes/json", peering.RequireAuth(s.db, s.getPeerNodes)),
+		// POST
+		daemon.NewPostRoute("/peers/token", s.postPeersToken),
+		daemon.NewPostRoute("/peers/establish", s.postPeersEstablish),
+		// DELETE
+		daemon.NewDeleteRoute("/peers/delete", s.deletePeers),
+	}
+}
+
+func (s *router) getPeers(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	peerings, err := s.db.ListPeerings(ctx)
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &peerings)
+}
+
+func (s *router) getPeerByName(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	name := vars["name"]
+	p, err := s.db.GetPeering(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &p)
+}
+
+// postPeersToken mints a bearer token the caller can hand to another labd
+// instance so that instance can establish a peering back to this cluster,
+// along with the seeder multiaddrs this cluster publishes content from. The
+// token authorizes its bearer to list nodes in the given cluster via
+// GET /peers/nodes/json.
+func (s *router) postPeersToken(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	cid := r.FormValue("cluster")
+	if cid == "" {
+		return errors.New("missing cluster")
+	}
+
+	token, err := peering.NewToken()
+	if err != nil {
+		return err
+	}
+
+	var seederAddrs []string
+	for _, addr := range s.seeder.Host().Addrs() {
+		seederAddrs = append(seederAddrs, addr.String()+"/p2p/"+s.seeder.Host().ID().String())
+	}
+
+	// Established is left false: this only records a token we handed out,
+	// not a peer we've ourselves established. postBenchmarksCreate's peer
+	// fan-out must skip these or it will try to dial a peer with no
+	// Endpoint.
+	_, err = s.db.CreatePeering(ctx, metadata.Peering{
+		Token:       token,
+		ClusterID:   cid,
+		SeederAddrs: seederAddrs,
+	})
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &struct {
+		Token       string   `json:"token"`
+		SeederAddrs []string `json:"seederAddrs"`
+	}{token, seederAddrs})
+}
+
+// getPeerNodes lists the nodes of the cluster that the caller's Bearer token
+// (validated by peering.RequireAuth) authorizes it to see. This is the route
+// peering.FetchNodes calls to fan a benchmark out to a peered cluster.
+func (s *router) getPeerNodes(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	p, ok := peering.FromContext(ctx)
+	if !ok {
+		return errors.New("missing authenticated peering")
+	}
+
+	nodes, err := s.db.ListNodes(ctx, p.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &nodes)
+}
+
+// postPeersEstablish stores a remote cluster's token and seeder addresses
+// so its nodes can be selected in scenario queries by the "peer:<name>"
+// label peering.FetchNodes adds to each of them. The nodes' own labels are
+// kept as they are; they are not prefixed with the peer's name.
+func (s *router) postPeersEstablish(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	name := r.FormValue("name")
+	if name == "" {
+		return errors.New("missing peer name")
+	}
+
+	p := metadata.Peering{
+		Name:         name,
+		Endpoint:     r.FormValue("endpoint"),
+		Token:        r.FormValue("token"),
+		TrustBundle:  r.FormValue("trust-bundle"),
+		SeederAddrs:  strings.Split(r.FormValue("seeder-addrs"), ","),
+		LastSyncedAt: time.Now(),
+		Established:  true,
+	}
+
+	p, err := s.db.CreatePeering(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	return daemon.WriteJSON(w, &p)
+}
+
+func (s *router) deletePeers(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	names := strings.Split(r.FormValue("names"), ",")
+
+	err := s.db.DeletePeerings(ctx, names...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	cid "github.com/ipfs/go-cid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DB is labd's metadata store. Every method not taking an explicit
+// transaction opens and commits its own; pass a context from
+// WithTransactionContext (inside an Update callback) to group calls into one
+// transaction.
+type DB interface {
+	GetCluster(ctx context.Context, id string) (Cluster, error)
+	GetScenario(ctx context.Context, id string) (Scenario, error)
+
+	ListNodes(ctx context.Context, clusterID string) ([]Node, error)
+
+	CreateBenchmark(ctx context.Context, benchmark Benchmark) (Benchmark, error)
+	GetBenchmark(ctx context.Context, id string) (Benchmark, error)
+	UpdateBenchmark(ctx context.Context, benchmark Benchmark) (Benchmark, error)
+	ListBenchmarks(ctx context.Context) ([]Benchmark, error)
+	LabelBenchmarks(ctx context.Context, ids []string, adds, removes []string) ([]Benchmark, error)
+	DeleteBenchmarks(ctx context.Context, ids ...string) error
+
+	CreateReport(ctx context.Context, id string, report Report) error
+	GetReport(ctx context.Context, id string) (Report, error)
+
+	// GetPlanCacheEntry and SetPlanCacheEntry cache the CID a scenario
+	// object transform resolved to, keyed by objectCacheKey, so repeated
+	// scenario plans skip re-running identical transforms.
+	GetPlanCacheEntry(ctx context.Context, key string) (cid.Cid, bool, error)
+	SetPlanCacheEntry(ctx context.Context, key string, c cid.Cid) error
+
+	// ListPeerings, CreatePeering, GetPeering, and DeletePeerings manage
+	// both tokens this cluster has issued and peers it has established; see
+	// Peering.Established.
+	ListPeerings(ctx context.Context) ([]Peering, error)
+	CreatePeering(ctx context.Context, p Peering) (Peering, error)
+	GetPeering(ctx context.Context, name string) (Peering, error)
+	DeletePeerings(ctx context.Context, names ...string) error
+
+	// Update runs fn in a single read-write transaction; use
+	// WithTransactionContext(ctx, tx) so calls made with the derived
+	// context join the same transaction instead of starting their own.
+	Update(ctx context.Context, fn func(tx *bolt.Tx) error) error
+}
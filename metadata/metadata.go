@@ -0,0 +1,161 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metadata holds the domain types persisted by labd and the DB
+// interface routers use to read and write them.
+package metadata
+
+import (
+	"context"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Node is an agent reachable at Address, tagged with Labels so scenario
+// queries can select it.
+type Node struct {
+	ID      string
+	Address string
+	Labels  []string
+}
+
+// TaskType identifies the kind of work a task performs.
+type TaskType string
+
+const (
+	TaskConnect TaskType = "connect"
+	TaskGet     TaskType = "get"
+	TaskSeed    TaskType = "seed"
+)
+
+// TaskStatus is the lifecycle state of a task or the node executing it.
+type TaskStatus string
+
+const (
+	TaskRunning TaskStatus = "running"
+	TaskDone    TaskStatus = "done"
+	TaskError   TaskStatus = "error"
+)
+
+// Task is a unit of work dispatched to a node.
+type Task struct {
+	Type    TaskType
+	Subject string
+	Status  TaskStatus
+	Start   time.Time
+	End     time.Time
+	Error   string
+}
+
+// NodeReport is one node's outcome for a benchmark run.
+type NodeReport struct {
+	ID     string
+	Status TaskStatus
+	Tasks  []Task
+}
+
+// ObjectDefinition describes how to transform a source into an IPLD DAG.
+type ObjectDefinition struct {
+	Type      string
+	Source    string
+	Layout    string
+	Chunker   string
+	HashFunc  string
+	RawLeaves bool
+}
+
+// ScenarioDefinition is the user-authored description of a scenario: the
+// objects it seeds, how nodes are connected, and the seed/benchmark queries
+// run against them.
+type ScenarioDefinition struct {
+	Objects   map[string]ObjectDefinition
+	Topology  Topology
+	Seed      map[string]string
+	Benchmark map[string]string
+}
+
+// ScenarioPlan is the concrete set of objects and tasks scenarios.Plan
+// resolved a ScenarioDefinition into.
+type ScenarioPlan struct {
+	Objects   map[string]cid.Cid
+	Seed      map[string]Task
+	Benchmark map[string]Task
+}
+
+// Cluster is a named set of nodes managed by a single NodeProvider.
+type Cluster struct {
+	ID       string
+	Labels   []string
+	Provider string
+}
+
+// Scenario is a named, stored ScenarioDefinition.
+type Scenario struct {
+	ID         string
+	Definition ScenarioDefinition
+}
+
+// BenchmarkStatus is the lifecycle state of a benchmark run.
+type BenchmarkStatus string
+
+const (
+	BenchmarkRunning   BenchmarkStatus = "running"
+	BenchmarkDone      BenchmarkStatus = "done"
+	BenchmarkError     BenchmarkStatus = "error"
+	BenchmarkCancelled BenchmarkStatus = "cancelled"
+)
+
+// Benchmark is a single run of a Scenario against a Cluster.
+type Benchmark struct {
+	ID       string
+	Status   BenchmarkStatus
+	Cluster  Cluster
+	Scenario Scenario
+	Plan     ScenarioPlan
+	Labels   []string
+}
+
+// ReportSummary aggregates the top-level, at-a-glance facts about a
+// benchmark run.
+type ReportSummary struct {
+	TotalTime time.Duration
+	Adjacency Adjacency
+	Metrics   MetricsSnapshot
+	Trace     string
+}
+
+// Report is the persisted outcome of a benchmark run.
+type Report struct {
+	Summary    ReportSummary
+	Nodes      []NodeReport
+	Queries    map[string][]string
+	Aggregates map[string]interface{}
+}
+
+type transactionContextKey struct{}
+
+// WithTransactionContext attaches tx to ctx so that DB calls made with the
+// returned context reuse tx instead of opening a new transaction.
+func WithTransactionContext(ctx context.Context, tx *bolt.Tx) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, tx)
+}
+
+// TransactionFromContext returns the *bolt.Tx attached by
+// WithTransactionContext, if any.
+func TransactionFromContext(ctx context.Context) (*bolt.Tx, bool) {
+	tx, ok := ctx.Value(transactionContextKey{}).(*bolt.Tx)
+	return tx, ok
+}
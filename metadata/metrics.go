@@ -0,0 +1,33 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+// MetricsSnapshot is a point-in-time read of a single benchmark run's
+// Prometheus collectors, keyed by sample name, persisted alongside its
+// report so it can be re-rendered without a live Prometheus server.
+type MetricsSnapshot struct {
+	Counters   map[string]float64
+	Gauges     map[string]float64
+	Histograms map[string]HistogramSnapshot
+}
+
+// HistogramSnapshot preserves enough of a Prometheus histogram to
+// reconstruct its latency distribution later: the overall count and sum,
+// plus the cumulative count observed at or below each bucket boundary.
+type HistogramSnapshot struct {
+	SampleCount uint64
+	SampleSum   float64
+	Buckets     map[float64]uint64
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import "time"
+
+// Peering is one side of a relationship between two labd clusters: either a
+// bearer token this cluster has issued to another (Established is false,
+// Name/Endpoint are empty) or a remote cluster this one has established a
+// peering with (Established is true).
+type Peering struct {
+	// Name identifies the peer for the "peer:<name>" label its nodes carry and for
+	// management routes. Empty for an issued-but-unestablished token.
+	Name string
+	// Endpoint is the peer's labd address, used to fetch its nodes. Empty
+	// for an issued-but-unestablished token.
+	Endpoint string
+	// Token authenticates RPCs between the two clusters: the side that
+	// issued it validates inbound requests against it, and the side that
+	// established the peering sends it as a bearer credential.
+	Token string
+	// ClusterID is the local cluster a Token authorizes access to. Set
+	// when the token is minted; read back by the node-list route once the
+	// presented Bearer token authenticates as this Peering.
+	ClusterID string
+	// TrustBundle is an opaque, peer-supplied blob used to validate its
+	// identity beyond the bearer token (e.g. a TLS trust root).
+	TrustBundle string
+	// SeederAddrs are the peer's seeder multiaddrs, merged into a
+	// benchmark's seeder list so nodes can fetch content from it.
+	SeederAddrs []string
+	// Established is true once this cluster has recorded the peer's
+	// Endpoint via postPeersEstablish. A Peering with Established false is
+	// only a token this cluster issued, not a peer it can fan out to.
+	Established  bool
+	LastSyncedAt time.Time
+}
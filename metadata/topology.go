@@ -0,0 +1,54 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+// TopologyType selects the connection strategy nodes.Connect uses to wire
+// up a cluster's nodes.
+type TopologyType string
+
+const (
+	// TopologyFullMesh connects every node to every other node. This is
+	// the default when Type is empty, preserving behavior from before
+	// Topology existed.
+	TopologyFullMesh TopologyType = "full-mesh"
+	// TopologyRing connects each node to its two immediate neighbors.
+	TopologyRing TopologyType = "ring"
+	// TopologyRandomKRegular connects each node to K randomly chosen nodes.
+	TopologyRandomKRegular TopologyType = "random-k-regular"
+	// TopologySmallWorld builds a Watts-Strogatz small-world graph.
+	TopologySmallWorld TopologyType = "small-world"
+	// TopologyBarabasiAlbert builds a Barabasi-Albert scale-free graph.
+	TopologyBarabasiAlbert TopologyType = "barabasi-albert"
+)
+
+// Topology configures how nodes.Connect wires up a cluster's nodes.
+type Topology struct {
+	Type TopologyType
+	// K is the degree used by TopologyRandomKRegular and
+	// TopologySmallWorld.
+	K int
+	// Beta is the rewiring probability used by TopologySmallWorld.
+	Beta float64
+	// M is the number of edges a new node attaches with in
+	// TopologyBarabasiAlbert.
+	M int
+	// Seed is the PRNG seed used by every randomized topology, so a
+	// scenario's connection graph is reproducible across runs.
+	Seed int64
+}
+
+// Adjacency is, for each node index, the indices of the peers nodes.Connect
+// dialed it to.
+type Adjacency [][]int
@@ -25,11 +25,14 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func Connect(ctx context.Context, nset p2plab.NodeSet) error {
+// Connect wires up nset according to topo, dialing each node only to the
+// peers its topology strategy selects rather than assuming a full mesh.
+func Connect(ctx context.Context, nset p2plab.NodeSet, topo metadata.Topology) (metadata.Adjacency, error) {
 	ns := nset.Slice()
 	peerAddrs := make([]string, len(ns))
 	collectPeerAddrs, ctx := errgroup.WithContext(ctx)
 	for i, n := range ns {
+		i, n := i, n
 		collectPeerAddrs.Go(func() error {
 			peerInfo, err := n.PeerInfo(ctx)
 			if err != nil {
@@ -47,23 +50,35 @@ func Connect(ctx context.Context, nset p2plab.NodeSet) error {
 
 	err := collectPeerAddrs.Wait()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	topology, err := NewTopology(topo)
+	if err != nil {
+		return nil, err
+	}
+	adjacency := topology.Peers(len(ns))
+
 	connectPeers, ctx := errgroup.WithContext(ctx)
-	for _, n := range ns {
+	for i, n := range ns {
+		i, n := i, n
 		connectPeers.Go(func() error {
+			var addrs []string
+			for _, j := range adjacency[i] {
+				addrs = append(addrs, peerAddrs[j])
+			}
+
 			return n.Run(ctx, metadata.Task{
 				Type:    metadata.TaskConnect,
-				Subject: strings.Join(peerAddrs, ","),
+				Subject: strings.Join(addrs, ","),
 			})
 		})
 	}
 
 	err = connectPeers.Wait()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
\ No newline at end of file
+	return metadata.Adjacency(adjacency), nil
+}
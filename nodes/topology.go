@@ -0,0 +1,245 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"math/rand"
+
+	"github.com/Netflix/p2plab/metadata"
+	"github.com/pkg/errors"
+)
+
+// Topology computes, for a given number of peers, the subset of peer
+// indices each peer should dial to form the graph.
+type Topology interface {
+	Peers(n int) [][]int
+}
+
+// NewTopology returns the Topology strategy described by def. An empty
+// def.Type defaults to a full mesh, preserving prior behavior.
+func NewTopology(def metadata.Topology) (Topology, error) {
+	switch def.Type {
+	case "", metadata.TopologyFullMesh:
+		return fullMeshTopology{}, nil
+	case metadata.TopologyRing:
+		return ringTopology{}, nil
+	case metadata.TopologyRandomKRegular:
+		if def.K <= 0 {
+			return nil, errors.Errorf("random-k-regular topology requires k > 0")
+		}
+		return randomKRegularTopology{k: def.K, seed: def.Seed}, nil
+	case metadata.TopologySmallWorld:
+		if def.K <= 0 {
+			return nil, errors.Errorf("small-world topology requires k > 0")
+		}
+		return smallWorldTopology{k: def.K, beta: def.Beta, seed: def.Seed}, nil
+	case metadata.TopologyBarabasiAlbert:
+		if def.M <= 0 {
+			return nil, errors.Errorf("barabasi-albert topology requires m > 0")
+		}
+		return barabasiAlbertTopology{m: def.M, seed: def.Seed}, nil
+	default:
+		return nil, errors.Errorf("unrecognized topology type %q", def.Type)
+	}
+}
+
+// fullMeshTopology connects every peer to every other peer.
+type fullMeshTopology struct{}
+
+func (t fullMeshTopology) Peers(n int) [][]int {
+	adj := make([][]int, n)
+	for i := range adj {
+		for j := 0; j < n; j++ {
+			if i != j {
+				adj[i] = append(adj[i], j)
+			}
+		}
+	}
+	return adj
+}
+
+// ringTopology connects each peer to its two immediate neighbors.
+type ringTopology struct{}
+
+func (t ringTopology) Peers(n int) [][]int {
+	adj := make([][]int, n)
+	for i := range adj {
+		if n <= 1 {
+			continue
+		}
+		adj[i] = []int{(i + 1) % n, (i - 1 + n) % n}
+	}
+	return adj
+}
+
+// randomKRegularTopology connects each peer to k randomly chosen peers.
+type randomKRegularTopology struct {
+	k    int
+	seed int64
+}
+
+func (t randomKRegularTopology) Peers(n int) [][]int {
+	rng := rand.New(rand.NewSource(t.seed))
+	k := t.k
+	if k > n-1 {
+		k = n - 1
+	}
+
+	adj := make([][]int, n)
+	for i := range adj {
+		candidates := make([]int, 0, n-1)
+		for j := 0; j < n; j++ {
+			if j != i {
+				candidates = append(candidates, j)
+			}
+		}
+		rng.Shuffle(len(candidates), func(a, b int) {
+			candidates[a], candidates[b] = candidates[b], candidates[a]
+		})
+		adj[i] = append([]int{}, candidates[:k]...)
+	}
+	return adj
+}
+
+// smallWorldTopology builds a Watts-Strogatz graph: a ring lattice of degree
+// k (k+1 when k is odd) rewired with probability beta.
+type smallWorldTopology struct {
+	k    int
+	beta float64
+	seed int64
+}
+
+func (t smallWorldTopology) Peers(n int) [][]int {
+	rng := rand.New(rand.NewSource(t.seed))
+	k := t.k
+	if k > n-1 {
+		k = n - 1
+	}
+
+	neighbors := make([]map[int]bool, n)
+	for i := range neighbors {
+		neighbors[i] = make(map[int]bool)
+	}
+
+	connect := func(i, j int) {
+		neighbors[i][j] = true
+		neighbors[j][i] = true
+	}
+
+	half := k / 2
+	for i := 0; i < n; i++ {
+		for d := 1; d <= half; d++ {
+			connect(i, (i+d)%n)
+		}
+	}
+	if k%2 == 1 {
+		// k is odd: half above truncated away one ring edge per node.
+		// Add that missing ring layer at distance half+1 for every node
+		// (not just some of them), so an odd k (in particular k=1, where
+		// half is 0) still yields a connected ring instead of a perfect
+		// matching of disjoint pairs.
+		for i := 0; i < n; i++ {
+			connect(i, (i+half+1)%n)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := range neighbors[i] {
+			if j < i {
+				continue
+			}
+			if rng.Float64() < t.beta {
+				delete(neighbors[i], j)
+				delete(neighbors[j], i)
+
+				var newPeer int
+				for {
+					newPeer = rng.Intn(n)
+					if newPeer != i && !neighbors[i][newPeer] {
+						break
+					}
+				}
+				connect(i, newPeer)
+			}
+		}
+	}
+
+	adj := make([][]int, n)
+	for i, ns := range neighbors {
+		for j := range ns {
+			adj[i] = append(adj[i], j)
+		}
+	}
+	return adj
+}
+
+// barabasiAlbertTopology builds a scale-free graph by preferential
+// attachment, adding each new peer with m edges to existing peers weighted
+// by their current degree.
+type barabasiAlbertTopology struct {
+	m    int
+	seed int64
+}
+
+func (t barabasiAlbertTopology) Peers(n int) [][]int {
+	rng := rand.New(rand.NewSource(t.seed))
+	m := t.m
+	if m > n-1 {
+		m = n - 1
+	}
+
+	neighbors := make([]map[int]bool, n)
+	for i := range neighbors {
+		neighbors[i] = make(map[int]bool)
+	}
+
+	connect := func(i, j int) {
+		neighbors[i][j] = true
+		neighbors[j][i] = true
+	}
+
+	var targets []int
+	for i := 0; i < n; i++ {
+		if i == 0 {
+			continue
+		}
+
+		picks := make(map[int]bool)
+		for len(picks) < m && len(picks) < i {
+			var candidate int
+			if len(targets) == 0 {
+				candidate = rng.Intn(i)
+			} else {
+				candidate = targets[rng.Intn(len(targets))]
+			}
+			if candidate != i {
+				picks[candidate] = true
+			}
+		}
+
+		for target := range picks {
+			connect(i, target)
+			targets = append(targets, i, target)
+		}
+	}
+
+	adj := make([][]int, n)
+	for i, ns := range neighbors {
+		for j := range ns {
+			adj[i] = append(adj[i], j)
+		}
+	}
+	return adj
+}
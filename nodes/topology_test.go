@@ -0,0 +1,140 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"testing"
+
+	"github.com/Netflix/p2plab/metadata"
+)
+
+func isSymmetric(t *testing.T, adj [][]int) {
+	t.Helper()
+	for i, peers := range adj {
+		for _, j := range peers {
+			found := false
+			for _, k := range adj[j] {
+				if k == i {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("adjacency not symmetric: %d -> %d but not %d -> %d", i, j, j, i)
+			}
+		}
+	}
+}
+
+func isConnected(t *testing.T, adj [][]int) {
+	t.Helper()
+	n := len(adj)
+	if n == 0 {
+		return
+	}
+
+	seen := make([]bool, n)
+	queue := []int{0}
+	seen[0] = true
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, j := range adj[i] {
+			if !seen[j] {
+				seen[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	for i, ok := range seen {
+		if !ok {
+			t.Fatalf("node %d is unreachable, graph is disconnected", i)
+		}
+	}
+}
+
+func TestFullMeshTopology(t *testing.T) {
+	adj := fullMeshTopology{}.Peers(5)
+	for i, peers := range adj {
+		if len(peers) != 4 {
+			t.Fatalf("node %d: expected 4 peers, got %d", i, len(peers))
+		}
+	}
+	isSymmetric(t, adj)
+	isConnected(t, adj)
+}
+
+func TestRingTopology(t *testing.T) {
+	adj := ringTopology{}.Peers(5)
+	for i, peers := range adj {
+		if len(peers) != 2 {
+			t.Fatalf("node %d: expected 2 peers, got %d", i, len(peers))
+		}
+	}
+	isSymmetric(t, adj)
+	isConnected(t, adj)
+}
+
+func TestRandomKRegularTopology(t *testing.T) {
+	adj := randomKRegularTopology{k: 3, seed: 1}.Peers(10)
+	for i, peers := range adj {
+		if len(peers) != 3 {
+			t.Fatalf("node %d: expected 3 peers, got %d", i, len(peers))
+		}
+	}
+	isConnected(t, adj)
+}
+
+func TestSmallWorldTopologyOddK(t *testing.T) {
+	// K=1 is the natural minimal degree and, prior to handling odd K
+	// explicitly, k/2 truncation produced zero ring edges and left the
+	// graph fully disconnected.
+	adj := smallWorldTopology{k: 1, beta: 0, seed: 1}.Peers(10)
+	isSymmetric(t, adj)
+	isConnected(t, adj)
+
+	for i, peers := range adj {
+		if len(peers) == 0 {
+			t.Fatalf("node %d has no peers", i)
+		}
+	}
+}
+
+func TestSmallWorldTopologyEvenK(t *testing.T) {
+	adj := smallWorldTopology{k: 4, beta: 0.1, seed: 1}.Peers(20)
+	isSymmetric(t, adj)
+	isConnected(t, adj)
+}
+
+func TestBarabasiAlbertTopology(t *testing.T) {
+	adj := barabasiAlbertTopology{m: 2, seed: 1}.Peers(10)
+	isSymmetric(t, adj)
+	isConnected(t, adj)
+}
+
+func TestNewTopologyRejectsInvalidParams(t *testing.T) {
+	cases := []metadata.Topology{
+		{Type: metadata.TopologyRandomKRegular, K: 0},
+		{Type: metadata.TopologySmallWorld, K: 0},
+		{Type: metadata.TopologyBarabasiAlbert, M: 0},
+		{Type: "bogus"},
+	}
+	for _, def := range cases {
+		if _, err := NewTopology(def); err == nil {
+			t.Fatalf("NewTopology(%+v): expected error, got nil", def)
+		}
+	}
+}
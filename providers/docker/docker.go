@@ -0,0 +1,190 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Netflix/p2plab"
+	"github.com/Netflix/p2plab/metadata"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// defaultPollInterval is how often service task state is polled while
+// waiting for the swarm to converge on the desired replica count.
+const defaultPollInterval = 2 * time.Second
+
+// Settings configures the Docker Swarm node provider.
+type Settings struct {
+	// Endpoint is the Docker daemon endpoint, e.g. "unix:///var/run/docker.sock"
+	// or a remote "tcp://" address of a Swarm manager.
+	Endpoint string
+	// Network is the overlay network agent containers are attached to.
+	Network string
+	// Image is the p2plab agent image to run.
+	Image string
+}
+
+type provider struct {
+	root     string
+	settings Settings
+	client   *dockerclient.Client
+
+	mu       sync.Mutex
+	services map[string]string // node id -> swarm service name
+}
+
+// New returns a p2plab.NodeProvider that manages agent nodes as tasks of a
+// Docker Swarm service.
+func New(root string, settings Settings) (p2plab.NodeProvider, error) {
+	opts := []dockerclient.Opt{dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation()}
+	if settings.Endpoint != "" {
+		opts = append(opts, dockerclient.WithHost(settings.Endpoint))
+	}
+
+	cli, err := dockerclient.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create docker client")
+	}
+
+	return &provider{
+		root:     root,
+		settings: settings,
+		client:   cli,
+		services: make(map[string]string),
+	}, nil
+}
+
+func (p *provider) CreateNodes(ctx context.Context, ids []string) ([]metadata.Node, error) {
+	name := fmt.Sprintf("p2plab-agent-%d", time.Now().UnixNano())
+	replicas := uint64(len(ids))
+
+	var networks []swarm.NetworkAttachmentConfig
+	if p.settings.Network != "" {
+		networks = append(networks, swarm.NetworkAttachmentConfig{Target: p.settings.Network})
+	}
+
+	spec := swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Name: name},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{
+				Image: p.settings.Image,
+			},
+			Networks: networks,
+		},
+		Mode: swarm.ServiceMode{
+			Replicated: &swarm.ReplicatedService{Replicas: &replicas},
+		},
+		EndpointSpec: &swarm.EndpointSpec{
+			Ports: []swarm.PortConfig{
+				{TargetPort: 8990, PublishMode: swarm.PortConfigPublishModeHost},
+			},
+		},
+	}
+
+	resp, err := p.client.ServiceCreate(ctx, spec, types.ServiceCreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create swarm service %q", name)
+	}
+
+	tasks, err := p.waitForReady(ctx, resp.ID, len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	var ns []metadata.Node
+	p.mu.Lock()
+	for i, task := range tasks {
+		ns = append(ns, metadata.Node{
+			ID:      ids[i],
+			Address: fmt.Sprintf("tasks.%s:%d", name, 8990),
+			Labels:  []string{"docker", name, task.ID},
+		})
+		p.services[ids[i]] = name
+	}
+	p.mu.Unlock()
+
+	return ns, nil
+}
+
+// DestroyNodes removes only the swarm services this provider created for
+// ids, leaving every other service in the cluster untouched.
+func (p *provider) DestroyNodes(ctx context.Context, ids []string) error {
+	p.mu.Lock()
+	names := namesForIDs(p.services, ids)
+	p.mu.Unlock()
+
+	for name := range names {
+		err := p.client.ServiceRemove(ctx, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove swarm service %q", name)
+		}
+	}
+
+	p.mu.Lock()
+	for _, id := range ids {
+		delete(p.services, id)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// namesForIDs returns the swarm service names registered in services for
+// ids, deduplicated; an id with no registered service (already destroyed,
+// or never provisioned by this provider) contributes nothing.
+func namesForIDs(services map[string]string, ids []string) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, id := range ids {
+		if name, ok := services[id]; ok {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+func (p *provider) waitForReady(ctx context.Context, serviceID string, want int) ([]swarm.Task, error) {
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			tasks, err := p.client.TaskList(ctx, types.TaskListOptions{})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list swarm tasks")
+			}
+
+			var running []swarm.Task
+			for _, task := range tasks {
+				if task.ServiceID == serviceID && task.Status.State == swarm.TaskStateRunning {
+					running = append(running, task)
+				}
+			}
+
+			if len(running) >= want {
+				return running, nil
+			}
+		}
+	}
+}
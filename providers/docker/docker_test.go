@@ -0,0 +1,57 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import "testing"
+
+func TestNamesForIDs(t *testing.T) {
+	services := map[string]string{
+		"node-a": "svc-a",
+		"node-b": "svc-b",
+		"node-c": "svc-c",
+	}
+
+	names := namesForIDs(services, []string{"node-b"})
+
+	if len(names) != 1 {
+		t.Fatalf("expected exactly one name, got %v", names)
+	}
+	if _, ok := names["svc-b"]; !ok {
+		t.Fatalf("expected svc-b selected, got %v", names)
+	}
+}
+
+func TestNamesForIDsSkipsUnknown(t *testing.T) {
+	services := map[string]string{"node-a": "svc-a"}
+
+	names := namesForIDs(services, []string{"node-a", "never-provisioned"})
+
+	if len(names) != 1 {
+		t.Fatalf("expected unknown id to contribute nothing, got %v", names)
+	}
+	if _, ok := names["svc-a"]; !ok {
+		t.Fatalf("expected svc-a selected, got %v", names)
+	}
+}
+
+func TestNamesForIDsEmpty(t *testing.T) {
+	services := map[string]string{"node-a": "svc-a", "node-b": "svc-b"}
+
+	names := namesForIDs(services, nil)
+
+	if len(names) != 0 {
+		t.Fatalf("expected no names for no ids, got %v", names)
+	}
+}
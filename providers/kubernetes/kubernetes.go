@@ -0,0 +1,272 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Netflix/p2plab"
+	"github.com/Netflix/p2plab/metadata"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultPollInterval is how often readiness of the agent workload is
+// checked while waiting for pods to come up.
+const defaultPollInterval = 2 * time.Second
+
+type provider struct {
+	root      string
+	settings  Settings
+	clientset *kubernetes.Clientset
+	resources corev1.ResourceList
+
+	mu        sync.Mutex
+	workloads map[string]string // node id -> statefulset/daemonset+service name
+}
+
+// Settings mirrors providers.KubernetesSettings so this package does not
+// depend on its importer.
+type Settings struct {
+	Kubeconfig string
+	Namespace  string
+	Image      string
+	Workload   string
+	CPU        string
+	Memory     string
+}
+
+// New returns a p2plab.NodeProvider that manages agent nodes as pods in a
+// Kubernetes cluster, either as a StatefulSet or a DaemonSet.
+func New(root string, settings Settings) (p2plab.NodeProvider, error) {
+	if settings.Namespace == "" {
+		settings.Namespace = "default"
+	}
+	if settings.Workload == "" {
+		settings.Workload = "statefulset"
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", settings.Kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build kubernetes client config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes clientset")
+	}
+
+	resources, err := resourceList(settings.CPU, settings.Memory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider{
+		root:      root,
+		settings:  settings,
+		clientset: clientset,
+		resources: resources,
+		workloads: make(map[string]string),
+	}, nil
+}
+
+func (p *provider) CreateNodes(ctx context.Context, ids []string) ([]metadata.Node, error) {
+	name := fmt.Sprintf("p2plab-agent-%d", time.Now().UnixNano())
+	labels := map[string]string{"app": name}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: p.settings.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
+			Ports: []corev1.ServicePort{
+				{Name: "agent", Port: 8990},
+			},
+		},
+	}
+
+	_, err := p.clientset.CoreV1().Services(p.settings.Namespace).Create(ctx, svc, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create headless service")
+	}
+
+	podSpec := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "agent",
+					Image: p.settings.Image,
+					Ports: []corev1.ContainerPort{{ContainerPort: 8990}},
+					Resources: corev1.ResourceRequirements{
+						Requests: p.resources,
+					},
+				},
+			},
+		},
+	}
+
+	if p.settings.Workload == "daemonset" {
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: p.settings.Namespace, Labels: labels},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: podSpec,
+			},
+		}
+		_, err = p.clientset.AppsV1().DaemonSets(p.settings.Namespace).Create(ctx, ds, metav1.CreateOptions{})
+	} else {
+		replicas := int32(len(ids))
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: p.settings.Namespace, Labels: labels},
+			Spec: appsv1.StatefulSetSpec{
+				ServiceName: name,
+				Replicas:    &replicas,
+				Selector:    &metav1.LabelSelector{MatchLabels: labels},
+				Template:    podSpec,
+			},
+		}
+		_, err = p.clientset.AppsV1().StatefulSets(p.settings.Namespace).Create(ctx, sts, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s %q", p.settings.Workload, name)
+	}
+
+	pods, err := p.waitForReady(ctx, name, labels, len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	var ns []metadata.Node
+	p.mu.Lock()
+	for i, pod := range pods {
+		ns = append(ns, metadata.Node{
+			ID:      ids[i],
+			Address: fmt.Sprintf("%s.%s.%s.svc.cluster.local:8990", pod.Name, name, p.settings.Namespace),
+			Labels:  []string{"kubernetes", name},
+		})
+		p.workloads[ids[i]] = name
+	}
+	p.mu.Unlock()
+
+	return ns, nil
+}
+
+// DestroyNodes tears down only the workload/service pairs this provider
+// created for ids, leaving any other StatefulSet/DaemonSet/Service in the
+// namespace untouched.
+func (p *provider) DestroyNodes(ctx context.Context, ids []string) error {
+	p.mu.Lock()
+	names := namesForIDs(p.workloads, ids)
+	p.mu.Unlock()
+
+	for name := range names {
+		err := p.clientset.AppsV1().StatefulSets(p.settings.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			err = p.clientset.AppsV1().DaemonSets(p.settings.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete workload %q", name)
+		}
+
+		err = p.clientset.CoreV1().Services(p.settings.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete service %q", name)
+		}
+	}
+
+	p.mu.Lock()
+	for _, id := range ids {
+		delete(p.workloads, id)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// namesForIDs returns the workload names registered in workloads for ids,
+// deduplicated; an id with no registered workload (already destroyed, or
+// never provisioned by this provider) contributes nothing.
+func namesForIDs(workloads map[string]string, ids []string) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, id := range ids {
+		if name, ok := workloads[id]; ok {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+func (p *provider) waitForReady(ctx context.Context, name string, labels map[string]string, want int) ([]corev1.Pod, error) {
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			pods, err := p.clientset.CoreV1().Pods(p.settings.Namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("app=%s", labels["app"]),
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list pods")
+			}
+
+			var ready []corev1.Pod
+			for _, pod := range pods.Items {
+				if pod.Status.Phase == corev1.PodRunning {
+					ready = append(ready, pod)
+				}
+			}
+
+			if len(ready) >= want {
+				return ready, nil
+			}
+		}
+	}
+}
+
+func resourceList(cpu, memory string) (corev1.ResourceList, error) {
+	rl := corev1.ResourceList{}
+	if cpu != "" {
+		q, err := resource.ParseQuantity(cpu)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu quantity %q", cpu)
+		}
+		rl[corev1.ResourceCPU] = q
+	}
+	if memory != "" {
+		q, err := resource.ParseQuantity(memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory quantity %q", memory)
+		}
+		rl[corev1.ResourceMemory] = q
+	}
+	return rl, nil
+}
@@ -19,18 +19,35 @@ import (
 
 	"github.com/Netflix/p2plab"
 	"github.com/Netflix/p2plab/errdefs"
+	"github.com/Netflix/p2plab/providers/docker"
+	"github.com/Netflix/p2plab/providers/kubernetes"
 	"github.com/Netflix/p2plab/providers/terraform"
 	"github.com/pkg/errors"
 )
 
+// ProviderSettings carries the per-driver configuration block for whichever
+// providerType is selected. Only the struct matching the driver in use needs
+// to be populated.
 type ProviderSettings struct {
+	Kubernetes KubernetesSettings
+	Docker     DockerSettings
 }
 
+// KubernetesSettings configures the Kubernetes node provider.
+type KubernetesSettings = kubernetes.Settings
+
+// DockerSettings configures the Docker Swarm node provider.
+type DockerSettings = docker.Settings
+
 func GetNodeProvider(root, providerType string, settings ProviderSettings) (p2plab.NodeProvider, error) {
 	root = filepath.Join(root, providerType)
 	switch providerType {
 	case "terraform":
 		return terraform.New(root)
+	case "kubernetes":
+		return kubernetes.New(root, settings.Kubernetes)
+	case "docker":
+		return docker.New(root, settings.Docker)
 	default:
 		return nil, errors.Wrapf(errdefs.ErrInvalidArgument, "unrecognized node provider type %q", providerType)
 	}
@@ -16,10 +16,16 @@ package scenarios
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
 	"sync"
+	"time"
 
 	"github.com/Netflix/p2plab"
 	"github.com/Netflix/p2plab/actions"
+	"github.com/Netflix/p2plab/labd/metrics"
 	"github.com/Netflix/p2plab/metadata"
 	"github.com/Netflix/p2plab/query"
 	"github.com/Netflix/p2plab/transformers"
@@ -28,7 +34,18 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func Plan(ctx context.Context, sdef metadata.ScenarioDefinition, ts *transformers.Transformers, peer p2plab.Peer, lset p2plab.LabeledSet) (plan metadata.ScenarioPlan, queries map[string][]string, err error) {
+// Plan builds a scenario's object DAGs, seed tasks, and benchmark tasks. m
+// may be nil, in which case no metrics are recorded. db may be nil, in
+// which case object transforms are never served from or saved to the plan
+// cache.
+func Plan(ctx context.Context, sdef metadata.ScenarioDefinition, ts *transformers.Transformers, peer p2plab.Peer, lset p2plab.LabeledSet, m *metrics.Metrics, db metadata.DB) (plan metadata.ScenarioPlan, queries map[string][]string, err error) {
+	planStart := time.Now()
+	defer func() {
+		if m != nil {
+			m.PlanDuration.Observe(time.Since(planStart).Seconds())
+		}
+	}()
+
 	plan = metadata.ScenarioPlan{
 		Objects:   make(map[string]cid.Cid),
 		Seed:      make(map[string]metadata.Task),
@@ -42,18 +59,41 @@ func Plan(ctx context.Context, sdef metadata.ScenarioDefinition, ts *transformer
 	for name, odef := range sdef.Objects {
 		name, odef := name, odef
 		objects.Go(func() error {
+			opts := AddOptionsFromDefinition(odef)
+			key := objectCacheKey(odef)
+
+			if db != nil {
+				c, ok, err := db.GetPlanCacheEntry(gctx, key)
+				if err != nil {
+					return err
+				}
+				if ok {
+					zerolog.Ctx(ctx).Debug().Str("type", odef.Type).Str("source", odef.Source).Str("cid", c.String()).Msg("Reused cached object")
+					mu.Lock()
+					plan.Objects[name] = c
+					mu.Unlock()
+					return nil
+				}
+			}
+
 			t, err := ts.Get(odef.Type)
 			if err != nil {
 				return err
 			}
 
-			opts := AddOptionsFromDefinition(odef)
 			c, err := t.Transform(gctx, peer, odef.Source, opts...)
 			if err != nil {
 				return err
 			}
 			zerolog.Ctx(ctx).Debug().Str("type", odef.Type).Str("source", odef.Source).Str("cid", c.String()).Msg("Transformed object")
 
+			if db != nil {
+				err = db.SetPlanCacheEntry(gctx, key, c)
+				if err != nil {
+					return err
+				}
+			}
+
 			mu.Lock()
 			plan.Objects[name] = c
 			mu.Unlock()
@@ -67,6 +107,7 @@ func Plan(ctx context.Context, sdef metadata.ScenarioDefinition, ts *transformer
 	}
 
 	zerolog.Ctx(ctx).Info().Msg("Planning scenario seed")
+	seedStart := time.Now()
 	for q, a := range sdef.Seed {
 		qry, err := query.Parse(ctx, q)
 		if err != nil {
@@ -101,6 +142,9 @@ func Plan(ctx context.Context, sdef metadata.ScenarioDefinition, ts *transformer
 
 		plan.Seed = taskMap
 	}
+	if m != nil {
+		m.SeedDuration.Observe(time.Since(seedStart).Seconds())
+	}
 
 	zerolog.Ctx(ctx).Info().Msg("Planning scenario benchmark")
 	queries = make(map[string][]string)
@@ -160,3 +204,33 @@ func AddOptionsFromDefinition(odef metadata.ObjectDefinition) []p2plab.AddOption
 	}
 	return opts
 }
+
+// objectCacheKey hashes an object's type, source, and the same fields
+// AddOptionsFromDefinition reads off odef to build add options. Any change to
+// Chunker, Layout, RawLeaves, or HashFunc therefore invalidates the key.
+// Source is hashed as written (its path or address), not its contents, so a
+// source whose contents change under the same name keeps its cached CID.
+//
+// Each field is length-prefixed before hashing so that, e.g.,
+// Type="ab",Source="c" cannot collide with Type="a",Source="bc".
+func objectCacheKey(odef metadata.ObjectDefinition) string {
+	h := sha256.New()
+	writeField(h, odef.Type)
+	writeField(h, odef.Source)
+	writeField(h, odef.Layout)
+	writeField(h, odef.Chunker)
+	writeField(h, odef.HashFunc)
+	if odef.RawLeaves {
+		h.Write([]byte{1})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField hashes s prefixed with its length so field boundaries can't
+// shift between different odef values.
+func writeField(h hash.Hash, s string) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(s)))
+	h.Write(length[:])
+	h.Write([]byte(s))
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 Netflix, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scenarios
+
+import (
+	"testing"
+
+	"github.com/Netflix/p2plab/metadata"
+)
+
+func TestObjectCacheKeyDistinguishesFieldBoundaries(t *testing.T) {
+	a := metadata.ObjectDefinition{Type: "ab", Source: "c"}
+	b := metadata.ObjectDefinition{Type: "a", Source: "bc"}
+
+	if objectCacheKey(a) == objectCacheKey(b) {
+		t.Fatalf("objectCacheKey(%+v) and objectCacheKey(%+v) collided", a, b)
+	}
+}
+
+func TestObjectCacheKeyStable(t *testing.T) {
+	odef := metadata.ObjectDefinition{
+		Type:      "file",
+		Source:    "testdata/foo",
+		Layout:    "trickle",
+		Chunker:   "size-262144",
+		HashFunc:  "sha2-256",
+		RawLeaves: true,
+	}
+
+	if objectCacheKey(odef) != objectCacheKey(odef) {
+		t.Fatalf("objectCacheKey is not deterministic for identical inputs")
+	}
+}
+
+func TestObjectCacheKeyVariesWithRawLeaves(t *testing.T) {
+	odef := metadata.ObjectDefinition{Type: "file", Source: "testdata/foo"}
+	withRawLeaves := odef
+	withRawLeaves.RawLeaves = true
+
+	if objectCacheKey(odef) == objectCacheKey(withRawLeaves) {
+		t.Fatalf("objectCacheKey ignored RawLeaves")
+	}
+}